@@ -1,14 +1,21 @@
 package event
 
 import (
+	"context"
 	"errors"
 
 	"fmt"
 
 	"reflect"
 
-	"gopkg.in/mgo.v2"
-	"gopkg.in/mgo.v2/bson"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	mgobson "gopkg.in/mgo.v2/bson"
 )
 
 var (
@@ -17,28 +24,101 @@ var (
 )
 
 type rawEvent struct {
-	Id         bson.ObjectId `json:"id"        bson:"_id,omitempty"`
-	InstanceId bson.ObjectId
-	Type       EventType
-	Raw        *bson.Raw
+	Id            primitive.ObjectID `bson:"_id,omitempty"`
+	InstanceId    primitive.ObjectID `bson:"instanceid"`
+	Type          EventType          `bson:"type"`
+	Version       int64              `bson:"version,omitempty"`
+	AggregateType AggregateType      `bson:"aggregate_type,omitempty"`
+	Raw           bson.Raw           `bson:"raw"`
 }
 
+// AggregateType identifies the kind of aggregate an event belongs to, so
+// a single collection can hold the streams of more than one aggregate
+// kind.
+type AggregateType string
+
 type Event interface {
-	GetId() bson.ObjectId
-	GetInstanceId() bson.ObjectId
+	GetId() primitive.ObjectID
+	GetInstanceId() primitive.ObjectID
 	GetType() EventType
+	// GetVersion returns the event's position in its aggregate's stream,
+	// starting at 1. It is used to detect concurrent writers via
+	// AppendStream.
+	GetVersion() int64
+	GetAggregateType() AggregateType
 }
 
-func toBSONRaw(in interface{}) (raw *bson.Raw, err error) {
-	var b []byte
-	if b, err = bson.Marshal(in); err != nil {
-		return
+// legacyRegistry marshals/unmarshals the gopkg.in/mgo.v2 bson.ObjectId
+// type as a Mongo ObjectId, so events adapted with AdaptLegacyEvent can
+// still carry that type on their own fields after the migration to
+// go.mongodb.org/mongo-driver.
+var legacyRegistry = bson.NewRegistryBuilder().
+	RegisterTypeEncoder(reflect.TypeOf(mgobson.ObjectId("")), bsoncodec.ValueEncoderFunc(encodeLegacyObjectId)).
+	RegisterTypeDecoder(reflect.TypeOf(mgobson.ObjectId("")), bsoncodec.ValueDecoderFunc(decodeLegacyObjectId)).
+	Build()
+
+func encodeLegacyObjectId(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) error {
+	id := val.Interface().(mgobson.ObjectId)
+	oid, err := primitive.ObjectIDFromHex(id.Hex())
+	if err != nil {
+		return err
 	}
-	raw = new(bson.Raw)
-	if err = bson.Unmarshal(b, raw); err != nil {
-		return
+	return vw.WriteObjectID(oid)
+}
+
+func decodeLegacyObjectId(_ bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+	oid, err := vr.ReadObjectID()
+	if err != nil {
+		return err
 	}
-	return
+	val.Set(reflect.ValueOf(mgobson.ObjectIdHex(oid.Hex())))
+	return nil
+}
+
+// LegacyEvent is the pre-migration Event shape, built against
+// gopkg.in/mgo.v2's bson.ObjectId. AdaptLegacyEvent wraps one so it keeps
+// working against MongoEventStore.
+type LegacyEvent interface {
+	GetId() mgobson.ObjectId
+	GetInstanceId() mgobson.ObjectId
+	GetType() EventType
+}
+
+type legacyEventShim struct {
+	LegacyEvent
+}
+
+func (s legacyEventShim) GetId() primitive.ObjectID {
+	id, _ := primitive.ObjectIDFromHex(s.LegacyEvent.GetId().Hex())
+	return id
+}
+
+func (s legacyEventShim) GetInstanceId() primitive.ObjectID {
+	id, _ := primitive.ObjectIDFromHex(s.LegacyEvent.GetInstanceId().Hex())
+	return id
+}
+
+// GetVersion always returns 0: legacy events predate stream versioning,
+// so adapted events are treated as unversioned.
+func (s legacyEventShim) GetVersion() int64 {
+	return 0
+}
+
+// GetAggregateType always returns "": legacy events predate aggregate
+// typing.
+func (s legacyEventShim) GetAggregateType() AggregateType {
+	return ""
+}
+
+// AdaptLegacyEvent wraps an Event implementation written against the old
+// gopkg.in/mgo.v2 bson.ObjectId-based interface so it satisfies the
+// current Event interface unchanged.
+func AdaptLegacyEvent(e LegacyEvent) Event {
+	return legacyEventShim{e}
+}
+
+func toBSONRaw(in interface{}) (raw bson.Raw, err error) {
+	return bson.MarshalWithRegistry(legacyRegistry, in)
 }
 
 func buildEvent(e Event) (re rawEvent, err error) {
@@ -48,20 +128,22 @@ func buildEvent(e Event) (re rawEvent, err error) {
 	re.Id = e.GetId()
 	re.Type = e.GetType()
 	re.InstanceId = e.GetInstanceId()
+	re.Version = e.GetVersion()
+	re.AggregateType = e.GetAggregateType()
 	return
 }
 
 func fromEvent(out interface{}, raw rawEvent) (err error) {
-	err = raw.Raw.Unmarshal(out)
+	err = bson.UnmarshalWithRegistry(legacyRegistry, raw.Raw, out)
 	return
 }
 
 func validateEvent(ev Event) (err error) {
-	if !ev.GetId().Valid() {
+	if ev.GetId().IsZero() {
 		err = fmt.Errorf("Event not valid Id = %s", ev.GetId())
 		return
 	}
-	if !ev.GetInstanceId().Valid() {
+	if ev.GetInstanceId().IsZero() {
 		err = fmt.Errorf("Event not valid InstanceId = %s", ev.GetInstanceId())
 		return
 	}
@@ -74,6 +156,8 @@ type Options struct {
 	Address        string
 	DatabaseName   string
 	CollectionName string
+	Bus            EventBus
+	Registry       *EventRegistry
 }
 
 type Option func(opts *Options)
@@ -96,6 +180,17 @@ func CollectionName(name string) Option {
 	}
 }
 
+// Bus registers an EventBus that every successful Create/Update is
+// published to, so subscribers can react to committed events without
+// polling the store. Named Bus rather than EventBus: the interface type
+// is already named EventBus, and Go does not allow a function and a type
+// to share a name in the same package.
+func Bus(bus EventBus) Option {
+	return func(opts *Options) {
+		opts.Bus = bus
+	}
+}
+
 func newOptions(opts ...Option) *Options {
 	o := &Options{
 		DatabaseName:   "event-sourcing",
@@ -109,71 +204,106 @@ func newOptions(opts ...Option) *Options {
 	return o
 }
 
+// SessionContext is passed to a Transaction callback so it can be used in
+// place of a plain context.Context with any other store method, keeping
+// those calls inside the transaction.
+type SessionContext = mongo.SessionContext
+
 type EventStore interface {
-	Init(...Option) error
-	Create(Event) error
-	Update(Event) error
-	Get(bson.ObjectId, interface{}) error
-	List(out interface{}) error
-	ListFiltered(interface{}, bson.M) error
+	Init(context.Context, ...Option) error
+	Create(context.Context, Event) error
+	Update(context.Context, Event) error
+	Get(context.Context, primitive.ObjectID) (Event, error)
+	List(context.Context) ([]Event, error)
+	ListFiltered(context.Context, bson.M) ([]Event, error)
+	GetInto(context.Context, primitive.ObjectID, interface{}) error
+	ListInto(context.Context, interface{}) error
+	ListFilteredInto(context.Context, interface{}, bson.M) error
+	Transaction(context.Context, func(SessionContext) error) error
+	AppendStream(ctx context.Context, aggregateID primitive.ObjectID, expectedVersion int64, events ...Event) error
+	LoadStream(ctx context.Context, aggregateID primitive.ObjectID, fromVersion int64) ([]Event, error)
 }
 
 type MongoEventStore struct {
-	*mgo.Session
+	client  *mongo.Client
+	col     *mongo.Collection
 	options *Options
 }
 
-func (store *MongoEventStore) Init(options ...Option) (err error) {
-	store.options = newOptions(options...)
+func (store *MongoEventStore) Init(ctx context.Context, opts ...Option) (err error) {
+	store.options = newOptions(opts...)
 
-	if store.Session, err = mgo.Dial(store.options.Address); err != nil {
+	clientOptions := options.Client().ApplyURI("mongodb://" + store.options.Address).SetRegistry(legacyRegistry)
+	if store.client, err = mongo.Connect(ctx, clientOptions); err != nil {
 		return
 	}
-	col := store.Session.DB(store.options.DatabaseName).C(store.options.CollectionName)
-
-	index := mgo.Index{
-		Key: []string{"instanceid"},
-	}
-	err = col.EnsureIndex(index)
+	store.col = store.client.Database(store.options.DatabaseName).Collection(store.options.CollectionName)
+
+	// The {instanceid, version} index is unique so AppendStream can detect
+	// concurrent writers: a duplicate-key error means another writer has
+	// already claimed one of the same version numbers. It only covers
+	// documents that have a version, so events created via the unversioned
+	// Create/Update path (version 0, omitted from the document) don't
+	// collide with each other.
+	_, err = store.col.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "instanceid", Value: 1}}},
+		{
+			Keys: bson.D{{Key: "instanceid", Value: 1}, {Key: "version", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"version": bson.M{"$exists": true}}),
+		},
+	})
 	return
 }
 
-func (store *MongoEventStore) Create(event Event) (err error) {
+func (store *MongoEventStore) Create(ctx context.Context, event Event) (err error) {
 	if err = validateEvent(event); err != nil {
 		return
 	}
-	session := store.Session.Copy()
-	defer session.Close()
 	e, err := buildEvent(event)
 	if err != nil {
 		return
 	}
-	err = session.DB(store.options.DatabaseName).C(store.options.CollectionName).Insert(&e)
+	if _, err = store.col.InsertOne(ctx, e); err != nil {
+		return
+	}
+	store.publish(event)
 	return
 }
 
-func (store *MongoEventStore) Update(event Event) (err error) {
+func (store *MongoEventStore) Update(ctx context.Context, event Event) (err error) {
 	if err = validateEvent(event); err != nil {
 		return
 	}
-	session := store.Session.Copy()
-	defer session.Close()
-
 	e, err := buildEvent(event)
-
 	if err != nil {
 		return
 	}
-	err = session.DB(store.options.DatabaseName).C(store.options.CollectionName).Update(bson.M{"_id": e.Id}, e)
+	if _, err = store.col.ReplaceOne(ctx, bson.M{"_id": e.Id}, e); err != nil {
+		return
+	}
+	store.publish(event)
 	return
 }
 
-func (store *MongoEventStore) Get(id bson.ObjectId, out interface{}) (err error) {
-	session := store.Session.Copy()
-	defer session.Close()
+// publish forwards event to the configured EventBus, if any. Publishing
+// happens after the write has been acknowledged by Mongo, so subscribers
+// only ever see committed events.
+func (store *MongoEventStore) publish(event Event) {
+	if store.options.Bus == nil {
+		return
+	}
+	store.options.Bus.Publish(event)
+}
+
+// GetInto decodes the event with id into out.
+//
+// Deprecated: use Get, which decodes via the store's EventRegistry and
+// returns the correct concrete type for the stored event automatically.
+func (store *MongoEventStore) GetInto(ctx context.Context, id primitive.ObjectID, out interface{}) (err error) {
 	e := rawEvent{}
-	if err = session.DB(store.options.DatabaseName).C(store.options.CollectionName).Find(bson.M{"_id": id}).One(&e); err != nil {
-		if err == mgo.ErrNotFound {
+	if err = store.col.FindOne(ctx, bson.M{"_id": id}).Decode(&e); err != nil {
+		if err == mongo.ErrNoDocuments {
 			err = ErrNotFound
 		}
 		return
@@ -182,11 +312,18 @@ func (store *MongoEventStore) Get(id bson.ObjectId, out interface{}) (err error)
 	return
 }
 
-func (store *MongoEventStore) List(out interface{}) (err error) {
-	return store.list(out, nil)
+// ListInto decodes every event in the collection into out, which must be
+// a pointer to a slice of a single concrete event type. A collection
+// holding more than one event type will fail to decode correctly; List
+// does not have this limitation.
+//
+// Deprecated: use List, which decodes via the store's EventRegistry and
+// returns the correct concrete type for each stored event automatically.
+func (store *MongoEventStore) ListInto(ctx context.Context, out interface{}) (err error) {
+	return store.listInto(ctx, out, nil)
 }
 
-func (store *MongoEventStore) list(out interface{}, f bson.M) (err error) {
+func (store *MongoEventStore) listInto(ctx context.Context, out interface{}, f bson.M) (err error) {
 	ot := reflect.TypeOf(out)
 	ov := reflect.ValueOf(out)
 	if out == nil || ot.Kind() != reflect.Ptr || ot.Elem().Kind() != reflect.Slice {
@@ -194,25 +331,55 @@ func (store *MongoEventStore) list(out interface{}, f bson.M) (err error) {
 		return
 	}
 
-	session := store.Session.Copy()
-
-	var rawEvents []rawEvent
+	if f == nil {
+		f = bson.M{}
+	}
 
-	if err = session.DB(store.options.DatabaseName).C(store.options.CollectionName).Find(f).All(&rawEvents); err != nil {
+	cursor, err := store.col.Find(ctx, f)
+	if err != nil {
 		return
 	}
+	defer cursor.Close(ctx)
 
 	st := ot.Elem().Elem() //slice type
 
-	for _, re := range rawEvents {
+	for cursor.Next(ctx) {
+		var re rawEvent
+		if err = cursor.Decode(&re); err != nil {
+			return
+		}
 		event := reflect.New(st).Interface()
-		fromEvent(event, re)
+		if err = fromEvent(event, re); err != nil {
+			return
+		}
 		ov.Elem().Set(reflect.Append(ov.Elem(), reflect.ValueOf(event).Elem()))
 	}
-
+	err = cursor.Err()
 	return
 }
 
-func (store *MongoEventStore) ListFiltered(out interface{}, f bson.M) error {
-	return store.list(out, f)
+// ListFilteredInto is ListInto narrowed by f.
+//
+// Deprecated: use ListFiltered, which decodes via the store's
+// EventRegistry and returns the correct concrete type for each stored
+// event automatically.
+func (store *MongoEventStore) ListFilteredInto(ctx context.Context, out interface{}, f bson.M) error {
+	return store.listInto(ctx, out, f)
+}
+
+// Transaction runs fn inside a MongoDB multi-document transaction, so
+// callers can atomically append multiple events for one aggregate. Any
+// store method can be called from within fn by passing it the
+// SessionContext instead of the outer context.
+func (store *MongoEventStore) Transaction(ctx context.Context, fn func(SessionContext) error) (err error) {
+	session, err := store.client.StartSession()
+	if err != nil {
+		return
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		return nil, fn(sc)
+	})
+	return
 }
@@ -0,0 +1,215 @@
+package event
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ErrHandlerExists is returned by AddHandler when a handler with the same
+// HandlerType is already registered on the bus.
+var ErrHandlerExists = errors.New("Handler already registered")
+
+// HandlerType names a registered EventHandler within an EventBus.
+type HandlerType string
+
+// EventHandler reacts to events published by an EventBus. Handle is called
+// on a dedicated goroutine per handler, so a slow or failing handler does
+// not hold up the others while its buffer of pending events has room.
+type EventHandler interface {
+	HandlerType() HandlerType
+	Handle(Event) error
+}
+
+// HandlerOption narrows which events a handler receives when it is
+// registered with AddHandler.
+type HandlerOption func(*subscription)
+
+// MatchEvents restricts a handler to the given event types. Omitted, the
+// handler receives every event type.
+func MatchEvents(types ...EventType) HandlerOption {
+	return func(s *subscription) {
+		s.eventTypes = types
+	}
+}
+
+// MatchAggregate restricts a handler to events for a single aggregate
+// instance. Omitted, the handler receives events for every instance.
+func MatchAggregate(instanceId primitive.ObjectID) HandlerOption {
+	return func(s *subscription) {
+		s.instanceId = &instanceId
+	}
+}
+
+type subscription struct {
+	handler    EventHandler
+	eventTypes []EventType
+	instanceId *primitive.ObjectID
+	events     chan Event
+}
+
+func (s *subscription) matches(e Event) bool {
+	if len(s.eventTypes) > 0 {
+		matched := false
+		for _, t := range s.eventTypes {
+			if t == e.GetType() {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if s.instanceId != nil && *s.instanceId != e.GetInstanceId() {
+		return false
+	}
+	return true
+}
+
+// EventBus publishes committed events to registered handlers, so
+// projections and sagas can react to new events without polling the
+// store.
+type EventBus interface {
+	// Publish delivers an event to every matching handler. Each handler has
+	// its own worker goroutine and a buffer of pending events; Publish
+	// blocks while a matching handler's buffer is full.
+	Publish(Event) error
+	// AddHandler registers a handler, optionally narrowed with
+	// MatchEvents and/or MatchAggregate.
+	AddHandler(EventHandler, ...HandlerOption) error
+	// Close stops every handler goroutine and releases resources.
+	Close() error
+}
+
+// RetryPolicy controls how many times a handler worker retries a failed
+// Handle call, and how long it waits between attempts, before giving up
+// and moving on to the next event.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     func(attempt int) time.Duration
+}
+
+func defaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: 3,
+		Backoff: func(attempt int) time.Duration {
+			return time.Duration(attempt) * 100 * time.Millisecond
+		},
+	}
+}
+
+// BusOption configures an InProcessBus at construction time.
+type BusOption func(*InProcessBus)
+
+// WithRetryPolicy overrides the default retry/backoff applied when a
+// handler's Handle call returns an error.
+func WithRetryPolicy(policy RetryPolicy) BusOption {
+	return func(b *InProcessBus) {
+		b.retry = policy
+	}
+}
+
+// InProcessBus is an EventBus that dispatches over Go channels within the
+// same process. It is the only transport implemented so far. EventBus is
+// kept as a narrow interface specifically so that out-of-process
+// transports (Google Pub/Sub, NATS, ...) can be added later as
+// additional implementations without changing callers that depend only
+// on EventBus.
+type InProcessBus struct {
+	mu     sync.RWMutex
+	subs   map[HandlerType]*subscription
+	retry  RetryPolicy
+	closed chan struct{}
+	wg     sync.WaitGroup
+}
+
+// NewInProcessBus creates an EventBus that dispatches in-process over Go
+// channels, with one buffered channel and worker goroutine per handler.
+func NewInProcessBus(opts ...BusOption) *InProcessBus {
+	bus := &InProcessBus{
+		subs:   make(map[HandlerType]*subscription),
+		retry:  defaultRetryPolicy(),
+		closed: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(bus)
+	}
+	return bus
+}
+
+func (b *InProcessBus) AddHandler(handler EventHandler, opts ...HandlerOption) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, exists := b.subs[handler.HandlerType()]; exists {
+		return ErrHandlerExists
+	}
+
+	sub := &subscription{handler: handler, events: make(chan Event, 64)}
+	for _, opt := range opts {
+		opt(sub)
+	}
+	b.subs[handler.HandlerType()] = sub
+
+	b.wg.Add(1)
+	go b.worker(sub)
+	return nil
+}
+
+func (b *InProcessBus) Publish(e Event) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, sub := range b.subs {
+		if sub.matches(e) {
+			sub.events <- e
+		}
+	}
+	return nil
+}
+
+func (b *InProcessBus) Close() error {
+	close(b.closed)
+
+	b.mu.Lock()
+	for _, sub := range b.subs {
+		close(sub.events)
+	}
+	b.mu.Unlock()
+
+	b.wg.Wait()
+	return nil
+}
+
+func (b *InProcessBus) worker(sub *subscription) {
+	defer b.wg.Done()
+	for {
+		select {
+		case e, ok := <-sub.events:
+			if !ok {
+				return
+			}
+			b.deliver(sub, e)
+		case <-b.closed:
+			return
+		}
+	}
+}
+
+// deliver calls the handler, retrying on error according to the bus's
+// RetryPolicy. Delivery is therefore at-least-once: a handler that keeps
+// failing will see the event MaxAttempts times before it is dropped.
+func (b *InProcessBus) deliver(sub *subscription, e Event) {
+	var err error
+	for attempt := 1; attempt <= b.retry.MaxAttempts; attempt++ {
+		if err = sub.handler.Handle(e); err == nil {
+			return
+		}
+		if attempt < b.retry.MaxAttempts {
+			time.Sleep(b.retry.Backoff(attempt))
+		}
+	}
+}
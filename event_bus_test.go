@@ -0,0 +1,130 @@
+package event
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type recordingHandler struct {
+	handlerType HandlerType
+	mu          sync.Mutex
+	received    []Event
+	done        chan struct{}
+	want        int
+}
+
+func newRecordingHandler(handlerType HandlerType, want int) *recordingHandler {
+	return &recordingHandler{handlerType: handlerType, done: make(chan struct{}), want: want}
+}
+
+func (h *recordingHandler) HandlerType() HandlerType {
+	return h.handlerType
+}
+
+func (h *recordingHandler) Handle(e Event) error {
+	h.mu.Lock()
+	h.received = append(h.received, e)
+	n := len(h.received)
+	h.mu.Unlock()
+	if n == h.want {
+		close(h.done)
+	}
+	return nil
+}
+
+func (h *recordingHandler) waitForAll(t *testing.T) {
+	select {
+	case <-h.done:
+	case <-time.After(time.Second):
+		t.Fatalf("handler %s did not receive all %d events", h.handlerType, h.want)
+	}
+}
+
+// TestEventBusOrderingAndDelivery plays a known sequence of events through
+// two independent bus instances and asserts that each handler sees every
+// event, in the order it was published.
+func TestEventBusOrderingAndDelivery(t *testing.T) {
+	instanceId := primitive.NewObjectID()
+	sequence := make([]*testEvent, 10)
+	for i := range sequence {
+		sequence[i] = &testEvent{Id: primitive.NewObjectID(), InstanceId: instanceId, String: "sequence event"}
+	}
+
+	busA := NewInProcessBus()
+	busB := NewInProcessBus()
+	defer busA.Close()
+	defer busB.Close()
+
+	handlerA := newRecordingHandler("handler-a", len(sequence))
+	handlerB := newRecordingHandler("handler-b", len(sequence))
+
+	if err := busA.AddHandler(handlerA); err != nil {
+		t.Fatal(err)
+	}
+	if err := busB.AddHandler(handlerB); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, e := range sequence {
+		if err := busA.Publish(e); err != nil {
+			t.Fatal(err)
+		}
+		if err := busB.Publish(e); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	handlerA.waitForAll(t)
+	handlerB.waitForAll(t)
+
+	for i, e := range sequence {
+		if handlerA.received[i].GetId() != e.GetId() {
+			t.Errorf("bus A: expected event %d to be %v, got %v", i, e.GetId(), handlerA.received[i].GetId())
+		}
+		if handlerB.received[i].GetId() != e.GetId() {
+			t.Errorf("bus B: expected event %d to be %v, got %v", i, e.GetId(), handlerB.received[i].GetId())
+		}
+	}
+}
+
+// TestEventBusMatchEvents asserts that a handler registered with
+// MatchEvents only receives events of the matching type.
+func TestEventBusMatchEvents(t *testing.T) {
+	bus := NewInProcessBus()
+	defer bus.Close()
+
+	handler := newRecordingHandler("typed-handler", 1)
+	if err := bus.AddHandler(handler, MatchEvents(EventType(101))); err != nil {
+		t.Fatal(err)
+	}
+
+	nonMatching := &otherTestEvent{Id: primitive.NewObjectID(), InstanceId: primitive.NewObjectID(), Number: 1}
+	bus.Publish(nonMatching)
+	matching := &testEvent{Id: primitive.NewObjectID(), InstanceId: primitive.NewObjectID(), String: "matches"}
+	bus.Publish(matching)
+
+	handler.waitForAll(t)
+
+	if len(handler.received) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(handler.received))
+	}
+	if handler.received[0].GetId() != matching.GetId() {
+		t.Error("handler received the wrong event")
+	}
+}
+
+func TestEventBusAddHandlerDuplicate(t *testing.T) {
+	bus := NewInProcessBus()
+	defer bus.Close()
+
+	handler := newRecordingHandler("dup", 1)
+	if err := bus.AddHandler(handler); err != nil {
+		t.Fatal(err)
+	}
+	if err := bus.AddHandler(handler); err != ErrHandlerExists {
+		t.Errorf("Expected error = %s, Actual = %s", ErrHandlerExists, err)
+	}
+}
@@ -0,0 +1,242 @@
+package event
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ProjectionName identifies a registered Projection's checkpoint in the
+// checkpoint collection.
+type ProjectionName string
+
+// Projection reacts to committed events as part of a named, checkpointed
+// read model. Project is called once per matching event, in commit
+// order.
+type Projection interface {
+	ProjectionName() ProjectionName
+	EventType() EventType
+	Project(Event) error
+}
+
+type projectionCheckpoint struct {
+	ProjectionName ProjectionName `bson:"_id"`
+	ResumeToken    bson.Raw       `bson:"resume_token,omitempty"`
+}
+
+// ProjectorOptions configures a Projector.
+type ProjectorOptions struct {
+	CheckpointCollectionName string
+	OnError                  func(ProjectionName, Event, error)
+}
+
+type ProjectorOption func(opts *ProjectorOptions)
+
+// CheckpointCollectionName overrides the default
+// "projection_checkpoints" collection used to persist resume tokens.
+func CheckpointCollectionName(name string) ProjectorOption {
+	return func(opts *ProjectorOptions) {
+		opts.CheckpointCollectionName = name
+	}
+}
+
+// OnProjectionError registers a handler called whenever a Projection's
+// Project method returns an error. The Projector reports the failure via
+// fn and moves on to the next event; the failed event is not retried.
+func OnProjectionError(fn func(ProjectionName, Event, error)) ProjectorOption {
+	return func(opts *ProjectorOptions) {
+		opts.OnError = fn
+	}
+}
+
+func newProjectorOptions(opts ...ProjectorOption) *ProjectorOptions {
+	o := &ProjectorOptions{
+		CheckpointCollectionName: "projection_checkpoints",
+		OnError:                  func(ProjectionName, Event, error) {},
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// Projector tails the event collection via MongoDB change streams and
+// dispatches each newly committed event to its registered Projection, so
+// read models stay up to date without polling ListFiltered. Each
+// projection tails its own change stream from its own persisted resume
+// token, so registering a new projection never disturbs the position of
+// one that is already running.
+type Projector struct {
+	events      *MongoEventStore
+	checkpoints *mongo.Collection
+	options     *ProjectorOptions
+
+	mu          sync.Mutex
+	projections map[ProjectionName]Projection
+}
+
+// NewProjector creates a Projector backed by events' client and
+// database.
+func NewProjector(events *MongoEventStore, opts ...ProjectorOption) *Projector {
+	projOpts := newProjectorOptions(opts...)
+	return &Projector{
+		events:      events,
+		checkpoints: events.client.Database(events.options.DatabaseName).Collection(projOpts.CheckpointCollectionName),
+		options:     projOpts,
+		projections: make(map[ProjectionName]Projection),
+	}
+}
+
+// Register adds projection to the set driven by Run and Rebuild.
+func (p *Projector) Register(projection Projection) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.projections[projection.ProjectionName()] = projection
+}
+
+func (p *Projector) registered() []Projection {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	projections := make([]Projection, 0, len(p.projections))
+	for _, projection := range p.projections {
+		projections = append(projections, projection)
+	}
+	return projections
+}
+
+func (p *Projector) findProjection(name ProjectionName) Projection {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.projections[name]
+}
+
+func (p *Projector) checkpoint(ctx context.Context, name ProjectionName) (token bson.Raw, err error) {
+	var cp projectionCheckpoint
+	err = p.checkpoints.FindOne(ctx, bson.M{"_id": name}).Decode(&cp)
+	switch err {
+	case nil:
+		return cp.ResumeToken, nil
+	case mongo.ErrNoDocuments:
+		return nil, nil
+	default:
+		return nil, err
+	}
+}
+
+func (p *Projector) saveCheckpoint(ctx context.Context, name ProjectionName, token bson.Raw) error {
+	_, err := p.checkpoints.ReplaceOne(ctx,
+		bson.M{"_id": name},
+		projectionCheckpoint{ProjectionName: name, ResumeToken: token},
+		options.Replace().SetUpsert(true),
+	)
+	return err
+}
+
+// Run tails a change stream per registered projection concurrently, each
+// resuming from its own persisted checkpoint, and blocks until ctx is
+// cancelled or any one of them returns an error (in which case the
+// others are stopped too). Registering an additional projection with no
+// checkpoint yet only affects that projection's own stream; it never
+// rewinds or restarts another projection's stream.
+func (p *Projector) Run(ctx context.Context) error {
+	projections := p.registered()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	errs := make(chan error, len(projections))
+	var wg sync.WaitGroup
+	for _, projection := range projections {
+		wg.Add(1)
+		go func(projection Projection) {
+			defer wg.Done()
+			if err := p.runProjection(ctx, projection); err != nil && ctx.Err() == nil {
+				errs <- err
+				cancel()
+			}
+		}(projection)
+	}
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
+
+func (p *Projector) runProjection(ctx context.Context, projection Projection) error {
+	name := projection.ProjectionName()
+
+	resumeToken, err := p.checkpoint(ctx, name)
+	if err != nil {
+		return err
+	}
+
+	streamOpts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+	if resumeToken != nil {
+		streamOpts.SetResumeAfter(resumeToken)
+	}
+
+	stream, err := p.events.col.Watch(ctx, mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{
+			"operationType":     "insert",
+			"fullDocument.type": projection.EventType(),
+		}}},
+	}, streamOpts)
+	if err != nil {
+		return err
+	}
+	defer stream.Close(ctx)
+
+	for stream.Next(ctx) {
+		var change struct {
+			FullDocument rawEvent `bson:"fullDocument"`
+		}
+		if err := stream.Decode(&change); err != nil {
+			return err
+		}
+		event := &storedEvent{change.FullDocument}
+		if err := projection.Project(event); err != nil {
+			p.options.OnError(name, event, err)
+			continue
+		}
+		if err := p.saveCheckpoint(ctx, name, stream.ResumeToken()); err != nil {
+			p.options.OnError(name, event, err)
+		}
+	}
+	return stream.Err()
+}
+
+// Rebuild replays every currently stored event of name's event type
+// through it, ignoring (and not advancing) its checkpoint, so a read
+// model can be rebuilt from scratch. A later Run resumes from the
+// projection's existing checkpoint, not from where Rebuild stopped; with
+// no checkpoint it starts at events committed after its stream opens.
+func (p *Projector) Rebuild(ctx context.Context, name ProjectionName) error {
+	projection := p.findProjection(name)
+	if projection == nil {
+		return fmt.Errorf("no projection registered with name %q", name)
+	}
+
+	cursor, err := p.events.col.Find(ctx,
+		bson.M{"type": projection.EventType()},
+		options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}),
+	)
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var re rawEvent
+		if err := cursor.Decode(&re); err != nil {
+			return err
+		}
+		if err := projection.Project(&storedEvent{re}); err != nil {
+			p.options.OnError(name, &storedEvent{re}, err)
+		}
+	}
+	return cursor.Err()
+}
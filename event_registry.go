@@ -0,0 +1,125 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// ErrTypeNotRegistered is returned when a stored event's EventType has no
+// constructor registered on the store's EventRegistry.
+var ErrTypeNotRegistered = errors.New("Event type not registered")
+
+// ErrRegistryRequired is returned by Get, List and ListFiltered when the
+// store was not configured with an EventRegistry via the Registry
+// option.
+var ErrRegistryRequired = errors.New("MongoEventStore requires an EventRegistry to decode events by type")
+
+// EventRegistry maps an EventType to a constructor for its concrete Go
+// type, so a collection holding more than one event type can be decoded
+// without the caller naming a single type up front.
+type EventRegistry struct {
+	mu           sync.RWMutex
+	constructors map[EventType]func() Event
+}
+
+// NewEventRegistry creates an empty EventRegistry.
+func NewEventRegistry() *EventRegistry {
+	return &EventRegistry{constructors: make(map[EventType]func() Event)}
+}
+
+// Register associates eventType with new, a constructor returning a
+// fresh, zero-valued instance of the concrete Go type to decode that
+// event into.
+func (r *EventRegistry) Register(eventType EventType, new func() Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.constructors[eventType] = new
+}
+
+func (r *EventRegistry) construct(eventType EventType) (Event, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	new, ok := r.constructors[eventType]
+	if !ok {
+		return nil, ErrTypeNotRegistered
+	}
+	return new(), nil
+}
+
+// Registry configures the EventRegistry used to decode events returned
+// by Get, List and ListFiltered.
+func Registry(r *EventRegistry) Option {
+	return func(opts *Options) {
+		opts.Registry = r
+	}
+}
+
+func (store *MongoEventStore) decodeTyped(re rawEvent) (Event, error) {
+	if store.options.Registry == nil {
+		return nil, ErrRegistryRequired
+	}
+	event, err := store.options.Registry.construct(re.Type)
+	if err != nil {
+		return nil, err
+	}
+	if err = fromEvent(event, re); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
+
+// Get decodes the event with id using the store's EventRegistry,
+// returning the correct concrete type for whatever was stored.
+func (store *MongoEventStore) Get(ctx context.Context, id primitive.ObjectID) (Event, error) {
+	var re rawEvent
+	if err := store.col.FindOne(ctx, bson.M{"_id": id}).Decode(&re); err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return store.decodeTyped(re)
+}
+
+// List decodes every event in the collection using the store's
+// EventRegistry, so a collection holding more than one event type
+// decodes correctly.
+func (store *MongoEventStore) List(ctx context.Context) ([]Event, error) {
+	return store.listTyped(ctx, nil)
+}
+
+// ListFiltered is List narrowed by f.
+func (store *MongoEventStore) ListFiltered(ctx context.Context, f bson.M) ([]Event, error) {
+	return store.listTyped(ctx, f)
+}
+
+func (store *MongoEventStore) listTyped(ctx context.Context, f bson.M) (events []Event, err error) {
+	if f == nil {
+		f = bson.M{}
+	}
+
+	cursor, err := store.col.Find(ctx, f)
+	if err != nil {
+		return
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var re rawEvent
+		if err = cursor.Decode(&re); err != nil {
+			return
+		}
+		var event Event
+		if event, err = store.decodeTyped(re); err != nil {
+			return
+		}
+		events = append(events, event)
+	}
+	err = cursor.Err()
+	return
+}
@@ -0,0 +1,110 @@
+package event
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type otherTestEvent struct {
+	Id         primitive.ObjectID
+	InstanceId primitive.ObjectID
+	Number     int
+}
+
+func (e *otherTestEvent) GetId() primitive.ObjectID         { return e.Id }
+func (e *otherTestEvent) GetInstanceId() primitive.ObjectID { return e.InstanceId }
+func (e *otherTestEvent) GetType() EventType                { return EventType(103) }
+func (e *otherTestEvent) GetVersion() int64                 { return 0 }
+func (e *otherTestEvent) GetAggregateType() AggregateType   { return "" }
+
+func newTestRegistry() *EventRegistry {
+	registry := NewEventRegistry()
+	registry.Register(EventType(101), func() Event { return &testEvent{} })
+	registry.Register(EventType(103), func() Event { return &otherTestEvent{} })
+	return registry
+}
+
+func newTestRegistryStore(ctx context.Context, t *testing.T) *MongoEventStore {
+	teardownTestDatabase(ctx, "event-sourcing-test", "registry-tests")
+	store := &MongoEventStore{}
+	if err := store.Init(ctx, Address(mongoAddress), DatabaseName("event-sourcing-test"), CollectionName("registry-tests"), Registry(newTestRegistry())); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestListPolymorphic(t *testing.T) {
+	ctx := context.Background()
+	store := newTestRegistryStore(ctx, t)
+
+	te := &testEvent{Id: primitive.NewObjectID(), InstanceId: primitive.NewObjectID(), String: "a string event"}
+	oe := &otherTestEvent{Id: primitive.NewObjectID(), InstanceId: primitive.NewObjectID(), Number: 42}
+
+	if err := store.Create(ctx, te); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Create(ctx, oe); err != nil {
+		t.Fatal(err)
+	}
+
+	events, err := store.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("Expected 2 events, got %d", len(events))
+	}
+
+	for _, e := range events {
+		switch typed := e.(type) {
+		case *testEvent:
+			if typed.String != te.String {
+				t.Errorf("Expected %q, got %q", te.String, typed.String)
+			}
+		case *otherTestEvent:
+			if typed.Number != oe.Number {
+				t.Errorf("Expected %d, got %d", oe.Number, typed.Number)
+			}
+		default:
+			t.Errorf("Unexpected concrete type %T", e)
+		}
+	}
+}
+
+func TestGetWithoutRegistry(t *testing.T) {
+	ctx := context.Background()
+	teardownTestDatabase(ctx, "event-sourcing-test", "registry-tests-empty")
+	store := &MongoEventStore{}
+	if err := store.Init(ctx, Address(mongoAddress), DatabaseName("event-sourcing-test"), CollectionName("registry-tests-empty")); err != nil {
+		t.Fatal(err)
+	}
+
+	te := &testEvent{Id: primitive.NewObjectID(), InstanceId: primitive.NewObjectID(), String: "no registry configured"}
+	if err := store.Create(ctx, te); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := store.Get(ctx, te.GetId()); err != ErrRegistryRequired {
+		t.Errorf("Expected error = %s, Actual = %s", ErrRegistryRequired, err)
+	}
+}
+
+func TestGetUnknownEventType(t *testing.T) {
+	ctx := context.Background()
+	store := newTestRegistryStore(ctx, t)
+
+	te := &testEvent{Id: primitive.NewObjectID(), InstanceId: primitive.NewObjectID(), String: "known type"}
+	if err := store.Create(ctx, te); err != nil {
+		t.Fatal(err)
+	}
+
+	unregistered := NewEventRegistry()
+	store.options.Registry = unregistered
+
+	if _, err := store.Get(ctx, te.GetId()); err != ErrTypeNotRegistered {
+		t.Errorf("Expected error = %s, Actual = %s", ErrTypeNotRegistered, err)
+	}
+}
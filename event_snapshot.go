@@ -0,0 +1,147 @@
+package event
+
+import (
+	"context"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Aggregate is implemented by domain objects that can be rebuilt from a
+// stream of events. Rehydrate calls Apply once per event, in version
+// order.
+type Aggregate interface {
+	Apply(Event) error
+}
+
+type rawSnapshot struct {
+	InstanceId primitive.ObjectID `bson:"instanceid"`
+	Version    int64              `bson:"version"`
+	Type       AggregateType      `bson:"type"`
+	Raw        bson.Raw           `bson:"raw"`
+}
+
+type SnapshotOptions struct {
+	CollectionName string
+	Every          int
+}
+
+type SnapshotOption func(opts *SnapshotOptions)
+
+// SnapshotCollectionName overrides the default snapshot collection name.
+func SnapshotCollectionName(name string) SnapshotOption {
+	return func(opts *SnapshotOptions) {
+		opts.CollectionName = name
+	}
+}
+
+// SnapshotEvery makes AppendStream write a fresh snapshot whenever an
+// append leaves the stream at a version that is a multiple of n; an
+// append whose batch steps over a multiple without ending on one writes
+// no snapshot. A value <= 0 (the default) disables automatic snapshotting;
+// call Snapshot directly instead.
+func SnapshotEvery(n int) SnapshotOption {
+	return func(opts *SnapshotOptions) {
+		opts.Every = n
+	}
+}
+
+func newSnapshotOptions(opts ...SnapshotOption) *SnapshotOptions {
+	o := &SnapshotOptions{
+		CollectionName: "snapshot",
+	}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// SnapshotStore is a companion to MongoEventStore that persists periodic
+// snapshots of aggregate state, so Rehydrate doesn't need to replay an
+// aggregate's whole event stream on every load.
+type SnapshotStore struct {
+	events  *MongoEventStore
+	col     *mongo.Collection
+	options *SnapshotOptions
+}
+
+// NewSnapshotStore creates a SnapshotStore backed by events' client and
+// database, in its own collection.
+func NewSnapshotStore(ctx context.Context, events *MongoEventStore, opts ...SnapshotOption) (store *SnapshotStore, err error) {
+	snapOpts := newSnapshotOptions(opts...)
+	col := events.client.Database(events.options.DatabaseName).Collection(snapOpts.CollectionName)
+
+	if _, err = col.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "instanceid", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	}); err != nil {
+		return
+	}
+
+	store = &SnapshotStore{events: events, col: col, options: snapOpts}
+	return
+}
+
+// Snapshot persists aggregate's current state at version for
+// aggregateID, overwriting any previous snapshot for that aggregate.
+func (s *SnapshotStore) Snapshot(ctx context.Context, aggregateID primitive.ObjectID, aggregateType AggregateType, version int64, aggregate Aggregate) (err error) {
+	raw, err := bson.MarshalWithRegistry(legacyRegistry, aggregate)
+	if err != nil {
+		return
+	}
+	snap := rawSnapshot{InstanceId: aggregateID, Version: version, Type: aggregateType, Raw: raw}
+	_, err = s.col.ReplaceOne(ctx, bson.M{"instanceid": aggregateID}, snap, options.Replace().SetUpsert(true))
+	return
+}
+
+// AppendStream appends events via the underlying MongoEventStore, then
+// writes a fresh snapshot of aggregate if doing so satisfies the
+// SnapshotEvery policy.
+func (s *SnapshotStore) AppendStream(ctx context.Context, aggregateID primitive.ObjectID, expectedVersion int64, aggregateType AggregateType, aggregate Aggregate, events ...Event) (err error) {
+	if err = s.events.AppendStream(ctx, aggregateID, expectedVersion, events...); err != nil {
+		return
+	}
+	if s.options.Every <= 0 {
+		return
+	}
+	newVersion := expectedVersion + int64(len(events))
+	if newVersion%int64(s.options.Every) != 0 {
+		return
+	}
+	return s.Snapshot(ctx, aggregateID, aggregateType, newVersion, aggregate)
+}
+
+// Rehydrate loads the latest snapshot for aggregateID into target, if
+// one exists, then applies every subsequent event from the event store,
+// in version order, via target.Apply.
+func (s *SnapshotStore) Rehydrate(ctx context.Context, aggregateID primitive.ObjectID, target Aggregate) (err error) {
+	fromVersion := int64(1)
+
+	var snap rawSnapshot
+	err = s.col.FindOne(ctx, bson.M{"instanceid": aggregateID}).Decode(&snap)
+	switch err {
+	case nil:
+		if err = bson.UnmarshalWithRegistry(legacyRegistry, snap.Raw, target); err != nil {
+			return
+		}
+		fromVersion = snap.Version + 1
+	case mongo.ErrNoDocuments:
+		err = nil
+	default:
+		return
+	}
+
+	stream, err := s.events.LoadStream(ctx, aggregateID, fromVersion)
+	if err != nil {
+		return
+	}
+
+	for _, e := range stream {
+		if err = target.Apply(e); err != nil {
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,124 @@
+package event
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type counterAggregate struct {
+	Id    primitive.ObjectID
+	Count int
+}
+
+func (a *counterAggregate) Apply(e Event) error {
+	var payload streamTestEvent
+	if err := e.(Unmarshaler).Unmarshal(&payload); err != nil {
+		return err
+	}
+	a.Id = e.GetInstanceId()
+	a.Count++
+	return nil
+}
+
+func newTestSnapshotStore(ctx context.Context, t *testing.T, opts ...SnapshotOption) (*MongoEventStore, *SnapshotStore) {
+	store, err := newTestEventStore(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mongoStore := store.(*MongoEventStore)
+
+	snapshots, err := NewSnapshotStore(ctx, mongoStore, append([]SnapshotOption{SnapshotCollectionName("test-snapshots")}, opts...)...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return mongoStore, snapshots
+}
+
+func TestRehydrateWithoutSnapshot(t *testing.T) {
+	ctx := context.Background()
+	mongoStore, snapshots := newTestSnapshotStore(ctx, t)
+
+	aggregateID := primitive.NewObjectID()
+	events := []Event{
+		newStreamTestEvent(aggregateID, 1, "first"),
+		newStreamTestEvent(aggregateID, 2, "second"),
+		newStreamTestEvent(aggregateID, 3, "third"),
+	}
+	if err := mongoStore.AppendStream(ctx, aggregateID, 0, events...); err != nil {
+		t.Fatal(err)
+	}
+
+	aggregate := &counterAggregate{}
+	if err := snapshots.Rehydrate(ctx, aggregateID, aggregate); err != nil {
+		t.Fatal(err)
+	}
+
+	if aggregate.Count != 3 {
+		t.Errorf("Expected count 3, got %d", aggregate.Count)
+	}
+}
+
+func TestRehydrateFromSnapshot(t *testing.T) {
+	ctx := context.Background()
+	mongoStore, snapshots := newTestSnapshotStore(ctx, t)
+
+	aggregateID := primitive.NewObjectID()
+	if err := mongoStore.AppendStream(ctx, aggregateID, 0,
+		newStreamTestEvent(aggregateID, 1, "first"),
+		newStreamTestEvent(aggregateID, 2, "second"),
+	); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := snapshots.Snapshot(ctx, aggregateID, "stream-aggregate", 2, &counterAggregate{Id: aggregateID, Count: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := mongoStore.AppendStream(ctx, aggregateID, 2, newStreamTestEvent(aggregateID, 3, "third")); err != nil {
+		t.Fatal(err)
+	}
+
+	aggregate := &counterAggregate{}
+	if err := snapshots.Rehydrate(ctx, aggregateID, aggregate); err != nil {
+		t.Fatal(err)
+	}
+
+	if aggregate.Count != 3 {
+		t.Errorf("Expected count 3 (2 from snapshot + 1 replayed), got %d", aggregate.Count)
+	}
+}
+
+func TestAppendStreamSnapshotEveryPolicy(t *testing.T) {
+	ctx := context.Background()
+	mongoStore, snapshots := newTestSnapshotStore(ctx, t, SnapshotEvery(2))
+
+	aggregateID := primitive.NewObjectID()
+	aggregate := &counterAggregate{Id: aggregateID}
+
+	for i := 1; i <= 2; i++ {
+		aggregate.Count++
+		if err := snapshots.AppendStream(ctx, aggregateID, int64(i-1), "stream-aggregate", aggregate,
+			newStreamTestEvent(aggregateID, int64(i), "event"),
+		); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var snap rawSnapshot
+	if err := snapshots.col.FindOne(ctx, map[string]interface{}{"instanceid": aggregateID}).Decode(&snap); err != nil {
+		t.Fatalf("Expected a snapshot to have been written after 2 events: %s", err)
+	}
+	if snap.Version != 2 {
+		t.Errorf("Expected snapshot version 2, got %d", snap.Version)
+	}
+
+	stream, err := mongoStore.LoadStream(ctx, aggregateID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stream) != 2 {
+		t.Errorf("Expected 2 events appended via the underlying store, got %d", len(stream))
+	}
+}
@@ -0,0 +1,101 @@
+package event
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ErrConcurrencyConflict is returned by AppendStream when another writer
+// has already appended an event at one of the same version numbers,
+// detected via the unique {instanceid, version} index.
+var ErrConcurrencyConflict = errors.New("Concurrency conflict: aggregate stream modified since expected version")
+
+// AppendStream inserts events for a single aggregate as one ordered
+// batch, starting immediately after expectedVersion. Every event must
+// belong to aggregateID and carry versions expectedVersion+1,
+// expectedVersion+2, ... in order. It returns ErrConcurrencyConflict if
+// another writer has already appended an event at one of those versions.
+func (store *MongoEventStore) AppendStream(ctx context.Context, aggregateID primitive.ObjectID, expectedVersion int64, events ...Event) (err error) {
+	docs := make([]interface{}, len(events))
+	for i, e := range events {
+		if e.GetInstanceId() != aggregateID {
+			return fmt.Errorf("event %d has InstanceId %s, expected aggregate %s", i, e.GetInstanceId().Hex(), aggregateID.Hex())
+		}
+		wantVersion := expectedVersion + int64(i) + 1
+		if e.GetVersion() != wantVersion {
+			return fmt.Errorf("event %d has version %d, expected %d", i, e.GetVersion(), wantVersion)
+		}
+		re, buildErr := buildEvent(e)
+		if buildErr != nil {
+			return buildErr
+		}
+		docs[i] = re
+	}
+
+	if _, err = store.col.InsertMany(ctx, docs, options.InsertMany().SetOrdered(true)); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			err = ErrConcurrencyConflict
+		}
+		return
+	}
+	return
+}
+
+// Unmarshaler is implemented by the events that LoadStream returns and
+// Rehydrate passes to Aggregate.Apply, which carry their original payload undecoded. Callers outside
+// this package can type-assert to Unmarshaler and call Unmarshal with a
+// pointer to the concrete type they expect, rather than naming the
+// unexported type that implements it.
+type Unmarshaler interface {
+	Unmarshal(out interface{}) error
+}
+
+// LoadStream returns the events for aggregateID from fromVersion onward,
+// in version order, ready for replay against an aggregate's
+// Apply(Event) error method. Each returned Event also implements
+// Unmarshaler, so callers can recover its original payload.
+func (store *MongoEventStore) LoadStream(ctx context.Context, aggregateID primitive.ObjectID, fromVersion int64) (stream []Event, err error) {
+	cursor, err := store.col.Find(
+		ctx,
+		bson.M{"instanceid": aggregateID, "version": bson.M{"$gte": fromVersion}},
+		options.Find().SetSort(bson.D{{Key: "version", Value: 1}}),
+	)
+	if err != nil {
+		return
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var re rawEvent
+		if err = cursor.Decode(&re); err != nil {
+			return
+		}
+		stream = append(stream, &storedEvent{re})
+	}
+	err = cursor.Err()
+	return
+}
+
+// storedEvent adapts a rawEvent read back from a stream to the Event
+// interface using only its indexed fields, without decoding the original
+// payload. It implements Unmarshaler so callers can recover the payload.
+type storedEvent struct {
+	re rawEvent
+}
+
+func (s *storedEvent) GetId() primitive.ObjectID         { return s.re.Id }
+func (s *storedEvent) GetInstanceId() primitive.ObjectID { return s.re.InstanceId }
+func (s *storedEvent) GetType() EventType                { return s.re.Type }
+func (s *storedEvent) GetVersion() int64                 { return s.re.Version }
+func (s *storedEvent) GetAggregateType() AggregateType   { return s.re.AggregateType }
+
+// Unmarshal decodes the event's original payload into out.
+func (s *storedEvent) Unmarshal(out interface{}) error {
+	return fromEvent(out, s.re)
+}
@@ -0,0 +1,117 @@
+package event
+
+import (
+	"context"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type streamTestEvent struct {
+	Id            primitive.ObjectID
+	InstanceId    primitive.ObjectID
+	Version       int64
+	AggregateType AggregateType
+	String        string
+}
+
+func (e *streamTestEvent) GetId() primitive.ObjectID         { return e.Id }
+func (e *streamTestEvent) GetInstanceId() primitive.ObjectID { return e.InstanceId }
+func (e *streamTestEvent) GetType() EventType                { return EventType(102) }
+func (e *streamTestEvent) GetVersion() int64                 { return e.Version }
+func (e *streamTestEvent) GetAggregateType() AggregateType   { return e.AggregateType }
+
+func newStreamTestEvent(aggregateID primitive.ObjectID, version int64, s string) *streamTestEvent {
+	return &streamTestEvent{Id: primitive.NewObjectID(), InstanceId: aggregateID, Version: version, AggregateType: "stream-aggregate", String: s}
+}
+
+func TestAppendAndLoadStream(t *testing.T) {
+	ctx := context.Background()
+	store, err := newTestEventStore(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mongoStore := store.(*MongoEventStore)
+
+	aggregateID := primitive.NewObjectID()
+	events := []Event{
+		newStreamTestEvent(aggregateID, 1, "first"),
+		newStreamTestEvent(aggregateID, 2, "second"),
+		newStreamTestEvent(aggregateID, 3, "third"),
+	}
+
+	if err = mongoStore.AppendStream(ctx, aggregateID, 0, events...); err != nil {
+		t.Fatal(err)
+	}
+
+	stream, err := mongoStore.LoadStream(ctx, aggregateID, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stream) != 3 {
+		t.Fatalf("Expected 3 events, got %d", len(stream))
+	}
+
+	for i, e := range stream {
+		if e.GetVersion() != int64(i+1) {
+			t.Errorf("Expected event %d to have version %d, got %d", i, i+1, e.GetVersion())
+		}
+		stored := e.(*storedEvent)
+		var decoded streamTestEvent
+		if err = stored.Unmarshal(&decoded); err != nil {
+			t.Fatal(err)
+		}
+		if decoded.String != events[i].(*streamTestEvent).String {
+			t.Errorf("Expected payload %q, got %q", events[i].(*streamTestEvent).String, decoded.String)
+		}
+	}
+}
+
+func TestAppendStreamConcurrencyConflict(t *testing.T) {
+	ctx := context.Background()
+	store, err := newTestEventStore(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mongoStore := store.(*MongoEventStore)
+
+	aggregateID := primitive.NewObjectID()
+	if err = mongoStore.AppendStream(ctx, aggregateID, 0, newStreamTestEvent(aggregateID, 1, "first")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = mongoStore.AppendStream(ctx, aggregateID, 0, newStreamTestEvent(aggregateID, 1, "conflicting")); err != ErrConcurrencyConflict {
+		t.Errorf("Expected error = %s, Actual = %s", ErrConcurrencyConflict, err)
+	}
+}
+
+func TestAppendStreamFromVersion(t *testing.T) {
+	ctx := context.Background()
+	store, err := newTestEventStore(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mongoStore := store.(*MongoEventStore)
+
+	aggregateID := primitive.NewObjectID()
+	events := []Event{
+		newStreamTestEvent(aggregateID, 1, "first"),
+		newStreamTestEvent(aggregateID, 2, "second"),
+	}
+	if err = mongoStore.AppendStream(ctx, aggregateID, 0, events...); err != nil {
+		t.Fatal(err)
+	}
+
+	stream, err := mongoStore.LoadStream(ctx, aggregateID, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stream) != 1 {
+		t.Fatalf("Expected 1 event, got %d", len(stream))
+	}
+	if stream[0].GetVersion() != 2 {
+		t.Errorf("Expected version 2, got %d", stream[0].GetVersion())
+	}
+}
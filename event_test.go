@@ -1,27 +1,29 @@
 package event
 
 import (
+	"context"
+	"fmt"
 	"log"
 	"testing"
 
-	"fmt"
-
-	"gopkg.in/mgo.v2"
-	"gopkg.in/mgo.v2/bson"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 const mongoAddress = "192.168.3.165:27017"
 
 type testEvent struct {
-	Id         bson.ObjectId
-	InstanceId bson.ObjectId
+	Id         primitive.ObjectID
+	InstanceId primitive.ObjectID
 	String     string
 }
 
-func (e *testEvent) GetId() bson.ObjectId {
+func (e *testEvent) GetId() primitive.ObjectID {
 	return e.Id
 }
-func (e *testEvent) GetInstanceId() bson.ObjectId {
+func (e *testEvent) GetInstanceId() primitive.ObjectID {
 	return e.InstanceId
 }
 
@@ -29,39 +31,48 @@ func (e *testEvent) GetType() EventType {
 	return EventType(101)
 }
 
-func teardownTestDatabase(dbName string, collectionName string) (err error) {
-	session, err := mgo.Dial(mongoAddress)
+func (e *testEvent) GetVersion() int64 {
+	return 0
+}
+
+func (e *testEvent) GetAggregateType() AggregateType {
+	return "test-aggregate"
+}
+
+func teardownTestDatabase(ctx context.Context, dbName string, collectionName string) (err error) {
+	client, err := mongo.Connect(ctx, mongoOptions())
 	if err != nil {
 		log.Printf("%s\n", err.Error())
 		return
 	}
-	col := session.DB(dbName).C(collectionName)
-	if err = col.DropCollection(); err != nil {
-		return
-	}
-	return
+	return client.Database(dbName).Collection(collectionName).Drop(ctx)
+}
+
+func mongoOptions() *options.ClientOptions {
+	return options.Client().ApplyURI("mongodb://" + mongoAddress)
 }
 
-func newTestEventStore() (EventStore, error) {
-	teardownTestDatabase("event-sourcing-test", "tests")
+func newTestEventStore(ctx context.Context) (EventStore, error) {
+	teardownTestDatabase(ctx, "event-sourcing-test", "tests")
 	store := &MongoEventStore{}
-	err := store.Init(Address(mongoAddress), DatabaseName("event-sourcing-test"), CollectionName("tests"))
+	err := store.Init(ctx, Address(mongoAddress), DatabaseName("event-sourcing-test"), CollectionName("tests"))
 
 	return store, err
 }
 
 func TestCreate(t *testing.T) {
-	store, err := newTestEventStore()
+	ctx := context.Background()
+	store, err := newTestEventStore(ctx)
 	if err != nil {
 		t.Fatal(err)
 	}
-	event := testEvent{bson.NewObjectId(), bson.NewObjectId(), "This is a test of some data"}
-	if err := store.Create(&event); err != nil {
+	event := testEvent{primitive.NewObjectID(), primitive.NewObjectID(), "This is a test of some data"}
+	if err := store.Create(ctx, &event); err != nil {
 		t.Fatal(err)
 	}
 
 	event1 := testEvent{}
-	store.Get(event.GetId(), &event1)
+	store.GetInto(ctx, event.GetId(), &event1)
 
 	if event1 != event {
 		t.Errorf("Actual %v, Expected %v", event1, event)
@@ -70,26 +81,28 @@ func TestCreate(t *testing.T) {
 }
 
 func TestCreateInvalid(t *testing.T) {
-	store, err := newTestEventStore()
+	ctx := context.Background()
+	store, err := newTestEventStore(ctx)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	event := testEvent{}
-	if err = store.Create(&event); err == nil {
+	if err = store.Create(ctx, &event); err == nil {
 		t.Fatal("Expected error")
 	}
 	t.Log(err)
 }
 
 func TestGetInvalid(t *testing.T) {
-	store, err := newTestEventStore()
+	ctx := context.Background()
+	store, err := newTestEventStore(ctx)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	event := testEvent{}
-	if err = store.Get(bson.NewObjectId(), &event); err == nil {
+	if err = store.GetInto(ctx, primitive.NewObjectID(), &event); err == nil {
 		t.Fatal("Expected error")
 	}
 	if err != ErrNotFound {
@@ -99,21 +112,22 @@ func TestGetInvalid(t *testing.T) {
 }
 
 func TestList(t *testing.T) {
-	store, err := newTestEventStore()
+	ctx := context.Background()
+	store, err := newTestEventStore(ctx)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	for i := 0; i < 100; i++ {
-		e := &testEvent{Id: bson.NewObjectId(), InstanceId: bson.NewObjectId(), String: fmt.Sprintf("This test %d", i)}
-		if err = store.Create(e); err != nil {
+		e := &testEvent{Id: primitive.NewObjectID(), InstanceId: primitive.NewObjectID(), String: fmt.Sprintf("This test %d", i)}
+		if err = store.Create(ctx, e); err != nil {
 			t.Fatal(err)
 		}
 	}
 
 	var list []testEvent
 
-	if err = store.List(&list); err != nil {
+	if err = store.ListInto(ctx, &list); err != nil {
 		t.Error(err)
 	}
 
@@ -132,40 +146,42 @@ func TestList(t *testing.T) {
 }
 
 func TestListInvalidArg(t *testing.T) {
-	store, err := newTestEventStore()
+	ctx := context.Background()
+	store, err := newTestEventStore(ctx)
 	if err != nil {
 		t.Fatal(err)
 	}
-	if err = store.List(nil); err != ErrInterfaceNotSlicePtr {
+	if err = store.ListInto(ctx, nil); err != ErrInterfaceNotSlicePtr {
 		t.Errorf("Expected error = %s, Actual = %s", ErrInterfaceNotSlicePtr, err)
 	}
 	sl := make([]testEvent, 0)
 
-	if err = store.List(sl); err != ErrInterfaceNotSlicePtr {
+	if err = store.ListInto(ctx, sl); err != ErrInterfaceNotSlicePtr {
 		t.Errorf("Expected error = %s, Actual = %s", ErrInterfaceNotSlicePtr, err)
 	}
 
-	if err = store.List(&sl); err != nil {
+	if err = store.ListInto(ctx, &sl); err != nil {
 		t.Errorf("Expected error = %s, Actual = %s", nil, err)
 	}
 }
 
 func TestListFiltered(t *testing.T) {
-	store, err := newTestEventStore()
+	ctx := context.Background()
+	store, err := newTestEventStore(ctx)
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	for i := 0; i < 100; i++ {
-		e := &testEvent{Id: bson.NewObjectId(), InstanceId: bson.NewObjectId(), String: fmt.Sprintf("This test %d", i)}
-		if err = store.Create(e); err != nil {
+		e := &testEvent{Id: primitive.NewObjectID(), InstanceId: primitive.NewObjectID(), String: fmt.Sprintf("This test %d", i)}
+		if err = store.Create(ctx, e); err != nil {
 			t.Fatal(err)
 		}
 	}
 
 	var list []testEvent
 
-	if err = store.ListFiltered(&list, bson.M{"raw.string": "This test 1"}); err != nil {
+	if err = store.ListFilteredInto(ctx, &list, bson.M{"raw.string": "This test 1"}); err != nil {
 		t.Error(err)
 	}
 
@@ -182,25 +198,26 @@ func TestListFiltered(t *testing.T) {
 }
 
 func TestUpdate(t *testing.T) {
-	store, err := newTestEventStore()
+	ctx := context.Background()
+	store, err := newTestEventStore(ctx)
 	if err != nil {
 		t.Fatal(err)
 	}
-	event := testEvent{bson.NewObjectId(), bson.NewObjectId(), "This is a test of some data"}
-	if err := store.Create(&event); err != nil {
+	event := testEvent{primitive.NewObjectID(), primitive.NewObjectID(), "This is a test of some data"}
+	if err := store.Create(ctx, &event); err != nil {
 		t.Fatal(err)
 	}
 
 	event1 := testEvent{}
-	store.Get(event.GetId(), &event1)
+	store.GetInto(ctx, event.GetId(), &event1)
 
 	event1.String = "UPDATED"
 
-	if err = store.Update(&event1); err != nil {
+	if err = store.Update(ctx, &event1); err != nil {
 		t.Fatal(err)
 	}
 
-	store.Get(event.GetId(), &event)
+	store.GetInto(ctx, event.GetId(), &event)
 
 	if event1 != event {
 		t.Errorf("Actual %v, Expected %v", event1, event)
@@ -209,14 +226,15 @@ func TestUpdate(t *testing.T) {
 }
 
 func BenchmarkCreate(b *testing.B) {
-	store, err := newTestEventStore()
+	ctx := context.Background()
+	store, err := newTestEventStore(ctx)
 	if err != nil {
 		b.Fatal(err)
 	}
 	b.ResetTimer() //dont include init and setup
 	for i := 0; i < b.N; i++ {
-		event := testEvent{bson.NewObjectId(), bson.NewObjectId(), "This is a test of some data"}
-		if err := store.Create(&event); err != nil {
+		event := testEvent{primitive.NewObjectID(), primitive.NewObjectID(), "This is a test of some data"}
+		if err := store.Create(ctx, &event); err != nil {
 			b.Fatal(err)
 		}
 	}